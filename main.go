@@ -12,7 +12,10 @@ func main() {
 	defer db.Close()
 
 	// Create a new circular list.
-	cl := circular.Open(db, "circular-test")
+	cl, err := circular.Open(db, "circular-test")
+	if err != nil {
+		panic(err)
+	}
 
 	// Add some values.
 	cl.Add([]byte("A"))
@@ -20,19 +23,19 @@ func main() {
 	cl.Add([]byte("C"))
 
 	// Try to add "B" only if it doesn't exist.
-	added := cl.AddNx([]byte("B")) // Will not add because "B" exists.
+	added, _ := cl.AddNx([]byte("B")) // Will not add because "B" exists.
 	fmt.Println("AddNx B added?", added)
 
 	// Add "E" with AddNx.
-	added = cl.AddNx([]byte("E"))
+	added, _ = cl.AddNx([]byte("E"))
 	fmt.Println("AddNx E added?", added)
 
 	// Get the current element.
-	val, idx, total := cl.Current()
+	val, idx, total, _ := cl.Current()
 	fmt.Printf("Current: %s (index %d of %d)\n", val, idx, total)
 
 	// Iterate to the next element.
-	next := cl.Next()
+	next, _ := cl.Next()
 	fmt.Println("Next:", string(next))
 
 	// Remove the current element.
@@ -40,24 +43,24 @@ func main() {
 	fmt.Println("Removed current element.")
 
 	// Get the new current element.
-	val, idx, total = cl.Current()
+	val, idx, total, _ = cl.Current()
 	fmt.Printf("Now Current: %s (index %d of %d)\n", val, idx, total)
 
-	next = cl.Next()
+	next, _ = cl.Next()
 	fmt.Println("Next:", string(next))
 
-	next = cl.Next()
+	next, _ = cl.Next()
 	fmt.Println("Next:", string(next))
 
-	next = cl.Next()
+	next, _ = cl.Next()
 	fmt.Println("Next:", string(next))
 
-	previous := cl.Previous()
+	previous, _ := cl.Previous()
 	fmt.Println("Previous:", string(previous))
 
-	previous = cl.Previous()
+	previous, _ = cl.Previous()
 	fmt.Println("Previous:", string(previous))
 
-	previous = cl.Previous()
+	previous, _ = cl.Previous()
 	fmt.Println("Previous:", string(previous))
-}
\ No newline at end of file
+}
@@ -0,0 +1,135 @@
+package circular
+
+import (
+	"bytes"
+
+	bolt "github.com/boltdb/bolt"
+)
+
+// keyAtIndex walks the data keys from head and returns the key at the given
+// 0-based index, or nil if index is out of range.
+func keyAtIndex(b *bolt.Bucket, index int) []byte {
+	c := b.Cursor()
+	k, _ := seekFirstData(c)
+	for i := 0; k != nil; i++ {
+		if i == index {
+			return k
+		}
+		k, _ = nextData(c)
+	}
+	return nil
+}
+
+// keyByValue walks the data keys from head and returns the key of the first
+// element whose value equals v, or nil if none matches.
+func keyByValue(b *bolt.Bucket, v []byte) []byte {
+	c := b.Cursor()
+	for k, val := seekFirstData(c); k != nil; k, val = nextData(c) {
+		if bytes.Equal(val, v) {
+			return k
+		}
+	}
+	return nil
+}
+
+// CompareAndRemove atomically removes the current element if and only if
+// its value equals expected, advancing the pointer the same way Remove
+// does. It reports whether the removal happened, letting concurrent
+// workers retire an item they just used without racing another worker's
+// Remove.
+func (cl *CircularList) CompareAndRemove(expected []byte) (bool, error) {
+	var removed bool
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		curKey := getMetaCurrentKey(b)
+		if curKey == nil {
+			return ErrEmpty
+		}
+		if !bytes.Equal(b.Get(curKey), expected) {
+			return nil
+		}
+		if err := removeKeyBytes(b, curKey); err != nil {
+			return err
+		}
+		removed = true
+		return nil
+	})
+	if err != nil {
+		return false, wrapTxErr(err)
+	}
+	return removed, nil
+}
+
+// CompareAndSwap atomically replaces the current element's value with
+// newValue if and only if its current value equals expected, then
+// advances the pointer as Next would. It reports whether the swap
+// happened.
+func (cl *CircularList) CompareAndSwap(expected, newValue []byte) (bool, error) {
+	var swapped bool
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		curKey := getMetaCurrentKey(b)
+		if curKey == nil {
+			return ErrEmpty
+		}
+		if !bytes.Equal(b.Get(curKey), expected) {
+			return nil
+		}
+		if err := b.Put(curKey, newValue); err != nil {
+			return err
+		}
+		swapped = true
+		_, err = advance(b, 1)
+		return err
+	})
+	if err != nil {
+		return false, wrapTxErr(err)
+	}
+	return swapped, nil
+}
+
+// RemoveAt deletes the element at the given 0-based index. The current
+// pointer keeps referring to the same logical element, unless that element
+// is the one removed, in which case it moves to the element after it
+// (wrapping to head), as Remove does.
+func (cl *CircularList) RemoveAt(index int) error {
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		if index < 0 {
+			return ErrIndexOutOfRange
+		}
+		key := keyAtIndex(b, index)
+		if key == nil {
+			return ErrIndexOutOfRange
+		}
+		return removeKeyBytes(b, key)
+	})
+	return wrapTxErr(err)
+}
+
+// RemoveValue deletes the first element whose value equals v, adjusting
+// the current pointer as RemoveAt does. It returns ErrNotFound if no
+// element matches.
+func (cl *CircularList) RemoveValue(v []byte) error {
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		key := keyByValue(b, v)
+		if key == nil {
+			return ErrNotFound
+		}
+		return removeKeyBytes(b, key)
+	})
+	return wrapTxErr(err)
+}
@@ -1,17 +1,47 @@
 package circular
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 
 	bolt "github.com/boltdb/bolt"
 )
 
 const (
-	metaCurrentKey = "__current__" // key for the current pointer
+	metaCurrentKey = "__current__" // key for the current pointer (schema v3: a raw data key)
 	metaNextKey    = "__next__"    // key for the next auto-increment value
-	dataPrefix     = "i"           // prefix for each stored item key
+	metaSchemaKey  = "__schema__"  // key for the on-disk schema version
+	metaHeadKey    = "__head__"    // key for the first element's raw data key
+	metaTailKey    = "__tail__"    // key for the last element's raw data key
+	metaCountKey   = "__count__"   // key for the persisted element count
+
+	dataPrefixV1 = "i" // schema v1 data-key prefix: the ascii byte 'i'
+)
+
+// dataPrefix is the schema v2 data-key type tag: a single reserved byte
+// that can't collide with future record types (tombstones, indices,
+// secondary rings) the way an ascii prefix like dataPrefixV1 could.
+var dataPrefix = []byte{0x01}
+
+// Sentinel errors returned by CircularList methods. Callers should use
+// errors.Is to test for these rather than comparing strings.
+var (
+	// ErrEmpty is returned by operations that require at least one
+	// element (Current, Next, Previous, Remove) when the list holds none.
+	ErrEmpty = errors.New("circular: list is empty")
+	// ErrBucketNotFound is returned when the list's backing bucket is
+	// missing from the database, e.g. it was dropped out from under an
+	// already-open CircularList.
+	ErrBucketNotFound = errors.New("circular: bucket not found")
+	// ErrClosed is returned when an operation is attempted against a
+	// database that has already been closed.
+	ErrClosed = errors.New("circular: database is closed")
+	// ErrIndexOutOfRange is returned by RemoveAt when given an index
+	// outside [0, count).
+	ErrIndexOutOfRange = errors.New("circular: index out of range")
+	// ErrNotFound is returned by RemoveValue when no element matches.
+	ErrNotFound = errors.New("circular: value not found")
 )
 
 // encodeUint64 converts a uint64 to an 8-byte big-endian slice.
@@ -36,89 +66,173 @@ func intToKey(i int) []byte {
 // dataKey returns the full key for storing an item:
 // the dataPrefix followed by the 8-byte big-endian representation.
 func dataKey(i int) []byte {
-	prefix := []byte(dataPrefix)
+	prefix := dataPrefix
 	return append(prefix, intToKey(i)...)
 }
 
-// CircularList represents a persistent circular list stored in a single BoltDB bucket.
+// wrapTxErr normalizes errors coming back from a bolt transaction into the
+// package's sentinel errors where applicable, leaving everything else
+// untouched.
+func wrapTxErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		return fmt.Errorf("%w: %v", ErrClosed, err)
+	}
+	return err
+}
+
+// CircularList represents a persistent circular list stored in a BoltDB
+// bucket, optionally nested under a path of parent buckets.
 type CircularList struct {
 	DB         *bolt.DB
 	BucketName []byte
+	Path       []string
+}
+
+// bucketCreator is the subset of *bolt.Tx and *bolt.Bucket that the path
+// helpers below need, so the same walking logic handles both the
+// database root and nested buckets.
+type bucketCreator interface {
+	Bucket(name []byte) *bolt.Bucket
+	CreateBucket(name []byte) (*bolt.Bucket, error)
+	CreateBucketIfNotExists(name []byte) (*bolt.Bucket, error)
+	DeleteBucket(name []byte) error
 }
 
-// New creates a new circular list in the given bucket,
-// deleting any existing bucket with that name.
-func New(db *bolt.DB, bucketName string) *CircularList {
+// createFreshBucketPath ensures every bucket along path[:len(path)-1]
+// exists, then deletes and recreates the bucket at the end of path,
+// returning it.
+func createFreshBucketPath(tx *bolt.Tx, path []string) (*bolt.Bucket, error) {
+	if len(path) == 0 {
+		return nil, errors.New("circular: bucket path must not be empty")
+	}
+	var cur bucketCreator = tx
+	for _, seg := range path[:len(path)-1] {
+		b, err := cur.CreateBucketIfNotExists([]byte(seg))
+		if err != nil {
+			return nil, err
+		}
+		cur = b
+	}
+	last := []byte(path[len(path)-1])
+	cur.DeleteBucket(last)
+	return cur.CreateBucket(last)
+}
+
+// openOrCreateBucketPath walks path, creating any missing bucket along the
+// way, and returns the bucket at the end of it.
+func openOrCreateBucketPath(tx *bolt.Tx, path []string) (*bolt.Bucket, error) {
+	if len(path) == 0 {
+		return nil, errors.New("circular: bucket path must not be empty")
+	}
+	var cur bucketCreator = tx
+	var b *bolt.Bucket
+	for _, seg := range path {
+		next, err := cur.CreateBucketIfNotExists([]byte(seg))
+		if err != nil {
+			return nil, err
+		}
+		b = next
+		cur = next
+	}
+	return b, nil
+}
+
+// New creates a new circular list at the given bucket path, deleting any
+// existing bucket at that path. A single name behaves as before; passing
+// more than one name nests the list's bucket under the preceding names,
+// creating intermediate buckets on demand.
+func New(db *bolt.DB, path ...string) (*CircularList, error) {
+	if len(path) == 0 {
+		return nil, errors.New("circular: bucket path must not be empty")
+	}
 	cl := &CircularList{
 		DB:         db,
-		BucketName: []byte(bucketName),
+		BucketName: []byte(path[len(path)-1]),
+		Path:       append([]string(nil), path...),
 	}
-	db.Update(func(tx *bolt.Tx) error {
-		tx.DeleteBucket(cl.BucketName)
-		b, err := tx.CreateBucket(cl.BucketName)
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := createFreshBucketPath(tx, cl.Path)
 		if err != nil {
 			return err
 		}
-		// Initialize meta keys.
-		if err := b.Put([]byte(metaCurrentKey), encodeUint64(0)); err != nil {
+		// Initialize meta keys. head/tail/current are left unset until the
+		// first Add; count starts at zero.
+		if err := b.Put([]byte(metaNextKey), encodeUint64(0)); err != nil {
+			return err
+		}
+		if err := setMetaCount(b, 0); err != nil {
 			return err
 		}
-		return b.Put([]byte(metaNextKey), encodeUint64(0))
+		return setSchemaVersion(b, currentSchemaVersion)
 	})
-	return cl
+	if err != nil {
+		return nil, wrapTxErr(err)
+	}
+	return cl, nil
 }
 
-// Open opens an existing circular list (or creates one if not present)
-// from the given bucket name, and ensures that meta keys exist.
-func Open(db *bolt.DB, bucketName string) *CircularList {
+// Open opens an existing circular list (or creates one, along with any
+// missing intermediate buckets, if not present) at the given bucket path,
+// ensures that meta keys exist, and migrates the bucket forward if it was
+// stamped with an older schema version. It refuses to proceed if the
+// bucket's schema version is newer than this package understands.
+func Open(db *bolt.DB, path ...string) (*CircularList, error) {
+	if len(path) == 0 {
+		return nil, errors.New("circular: bucket path must not be empty")
+	}
 	cl := &CircularList{
 		DB:         db,
-		BucketName: []byte(bucketName),
+		BucketName: []byte(path[len(path)-1]),
+		Path:       append([]string(nil), path...),
 	}
-	db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		if b == nil {
-			var err error
-			b, err = tx.CreateBucket(cl.BucketName)
-			if err != nil {
-				return err
-			}
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := openOrCreateBucketPath(tx, cl.Path)
+		if err != nil {
+			return err
 		}
-		// Ensure the meta keys exist.
-		if b.Get([]byte(metaCurrentKey)) == nil {
-			if err := b.Put([]byte(metaCurrentKey), encodeUint64(0)); err != nil {
+		if k, _ := b.Cursor().First(); k == nil {
+			// Bucket has no keys at all, so openOrCreateBucketPath must have
+			// just created it: initialize at the current schema. A
+			// pre-existing bucket always carries at least one key (a data
+			// key or a meta key), even if it's missing __next__, so this
+			// can't be bypassed by a partial/legacy bucket the way checking
+			// for __next__ alone could.
+			if err := b.Put([]byte(metaNextKey), encodeUint64(0)); err != nil {
 				return err
 			}
-		}
-		if b.Get([]byte(metaNextKey)) == nil {
-			if err := b.Put([]byte(metaNextKey), encodeUint64(0)); err != nil {
+			if err := setMetaCount(b, 0); err != nil {
 				return err
 			}
+			return setSchemaVersion(b, currentSchemaVersion)
 		}
-		return nil
+		return migrateBucket(b, currentSchemaVersion)
 	})
-	return cl
-}
-
-// getOrderedDataKeys scans the bucket for all keys with the dataPrefix,
-// returning them in sorted order.
-func getOrderedDataKeys(b *bolt.Bucket) ([][]byte, error) {
-	var keys [][]byte
-	c := b.Cursor()
-	prefix := []byte(dataPrefix)
-	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
-		keys = append(keys, k)
+	if err != nil {
+		return nil, wrapTxErr(err)
 	}
-	return keys, nil
+	return cl, nil
 }
 
-// getMetaCurrent reads the current pointer from the bucket.
-func getMetaCurrent(b *bolt.Bucket) (uint64, error) {
-	v := b.Get([]byte(metaCurrentKey))
-	if v == nil {
-		return 0, errors.New("meta current not found")
+// getBucket walks path from the transaction root and returns the bucket at
+// its end, or ErrBucketNotFound if any segment is missing.
+func getBucket(tx *bolt.Tx, path []string) (*bolt.Bucket, error) {
+	if len(path) == 0 {
+		return nil, ErrBucketNotFound
 	}
-	return decodeUint64(v), nil
+	b := tx.Bucket([]byte(path[0]))
+	for _, seg := range path[1:] {
+		if b == nil {
+			return nil, ErrBucketNotFound
+		}
+		b = b.Bucket([]byte(seg))
+	}
+	if b == nil {
+		return nil, ErrBucketNotFound
+	}
+	return b, nil
 }
 
 // getMetaNext reads the next auto-increment value from the bucket.
@@ -130,185 +244,146 @@ func getMetaNext(b *bolt.Bucket) (uint64, error) {
 	return decodeUint64(v), nil
 }
 
-func setMetaCurrent(b *bolt.Bucket, val uint64) error {
-	return b.Put([]byte(metaCurrentKey), encodeUint64(val))
-}
-
 func setMetaNext(b *bolt.Bucket, val uint64) error {
 	return b.Put([]byte(metaNextKey), encodeUint64(val))
 }
 
 // Add inserts a new value into the circular list using the next auto-increment key.
-func (cl *CircularList) Add(value []byte) {
-	cl.DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		next, err := getMetaNext(b)
+func (cl *CircularList) Add(value []byte) error {
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
 		if err != nil {
 			return err
 		}
-		key := dataKey(int(next))
-		if err := b.Put(key, value); err != nil {
+		return addOne(b, value)
+	})
+	return wrapTxErr(err)
+}
+
+// AddBatch inserts every value in values in a single transaction, so a
+// caller adding many items at once pays for one commit instead of one per
+// item.
+func (cl *CircularList) AddBatch(values [][]byte) error {
+	if len(values) == 0 {
+		return nil
+	}
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
 			return err
 		}
-		return setMetaNext(b, next+1)
+		for _, value := range values {
+			if err := addOne(b, value); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+	return wrapTxErr(err)
 }
 
 // AddNx adds a new value only if an identical value is not already present.
 // Returns true if the value was added; otherwise false.
-func (cl *CircularList) AddNx(value []byte) bool {
-	if cl.exists(value) {
-		return false
+func (cl *CircularList) AddNx(value []byte) (bool, error) {
+	found, err := cl.exists(value)
+	if err != nil {
+		return false, err
 	}
-	cl.Add(value)
-	return true
+	if found {
+		return false, nil
+	}
+	if err := cl.Add(value); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // exists scans the bucket for an item with an identical value.
-func (cl *CircularList) exists(value []byte) bool {
-	found := false
-	cl.DB.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		c := b.Cursor()
-		prefix := []byte(dataPrefix)
-		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-			if v != nil && bytes.Equal(v, value) {
-				found = true
-				break
-			}
+func (cl *CircularList) exists(value []byte) (bool, error) {
+	var found bool
+	err := cl.DB.View(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
 		}
-		return nil
+		var err2 error
+		found, err2 = valueExists(b, value)
+		return err2
 	})
-	return found
+	if err != nil {
+		return false, wrapTxErr(err)
+	}
+	return found, nil
 }
 
 // Remove deletes the current element from the list and advances the pointer.
-func (cl *CircularList) Remove() {
-	cl.DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		keys, err := getOrderedDataKeys(b)
-		if err != nil {
-			return err
-		}
-		count := len(keys)
-		if count == 0 {
-			return nil
-		}
-		cur, err := getMetaCurrent(b)
+func (cl *CircularList) Remove() error {
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
 		if err != nil {
 			return err
 		}
-		index := int(cur)
-		if index >= count {
-			index = 0
-		}
-		// Delete the current item.
-		if err := b.Delete(keys[index]); err != nil {
-			return err
-		}
-		newCount := count - 1
-		if newCount == 0 {
-			return setMetaCurrent(b, 0)
-		}
-		if index >= newCount {
-			index = 0
-		}
-		return setMetaCurrent(b, uint64(index))
+		return removeCurrent(b)
 	})
+	return wrapTxErr(err)
 }
 
-// Current returns the current element's value, its 0-based index, and the total count.
-func (cl *CircularList) Current() ([]byte, int, int) {
+// Current returns the current element's value, its 0-based index, and the
+// total count. The count and lookup are O(1); the index costs a single
+// cursor walk from head since it isn't itself part of the persisted state.
+func (cl *CircularList) Current() ([]byte, int, int, error) {
 	var value []byte
 	var index, count int
-	cl.DB.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		keys, err := getOrderedDataKeys(b)
+	err := cl.DB.View(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
 		if err != nil {
 			return err
 		}
-		count = len(keys)
-		if count == 0 {
-			return nil
-		}
-		cur, err := getMetaCurrent(b)
-		if err != nil {
-			return err
-		}
-		index = int(cur)
-		if index >= count {
-			index = 0
-		}
-		v := b.Get(keys[index])
-		if v != nil {
-			value = make([]byte, len(v))
-			copy(value, v)
-		}
-		return nil
+		var err2 error
+		value, index, count, err2 = currentOf(b)
+		return err2
 	})
-	return value, index, count
+	if err != nil {
+		return nil, 0, 0, wrapTxErr(err)
+	}
+	return value, index, count, nil
 }
 
-// Next advances the pointer (wrapping around) and returns the next element's value.
-func (cl *CircularList) Next() []byte {
+// Next advances the pointer (wrapping around) and returns the next
+// element's value via a single cursor Seek+Next, in O(log N) bolt page
+// depth rather than a full scan.
+func (cl *CircularList) Next() ([]byte, error) {
 	var value []byte
-	cl.DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		keys, err := getOrderedDataKeys(b)
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
 		if err != nil {
 			return err
 		}
-		count := len(keys)
-		if count == 0 {
-			return nil
-		}
-		cur, err := getMetaCurrent(b)
-		if err != nil {
-			return err
-		}
-		index := int(cur)
-		index = (index + 1) % count
-		if err := setMetaCurrent(b, uint64(index)); err != nil {
-			return err
-		}
-		v := b.Get(keys[index])
-		if v != nil {
-			value = make([]byte, len(v))
-			copy(value, v)
-		}
-		return nil
+		var err2 error
+		value, err2 = advance(b, 1)
+		return err2
 	})
-	return value
+	if err != nil {
+		return nil, wrapTxErr(err)
+	}
+	return value, nil
 }
 
-// Previous moves the pointer backward (wrapping around) and returns the previous element's value.
-func (cl *CircularList) Previous() []byte {
+// Previous moves the pointer backward (wrapping around) and returns the
+// previous element's value via a single cursor Seek+Prev.
+func (cl *CircularList) Previous() ([]byte, error) {
 	var value []byte
-	cl.DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(cl.BucketName)
-		keys, err := getOrderedDataKeys(b)
-		if err != nil {
-			return err
-		}
-		count := len(keys)
-		if count == 0 {
-			return nil
-		}
-		cur, err := getMetaCurrent(b)
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
 		if err != nil {
 			return err
 		}
-		index := int(cur)
-		index = (index - 1 + count) % count
-		if err := setMetaCurrent(b, uint64(index)); err != nil {
-			return err
-		}
-		v := b.Get(keys[index])
-		if v != nil {
-			value = make([]byte, len(v))
-			copy(value, v)
-		}
-		return nil
+		var err2 error
+		value, err2 = advance(b, -1)
+		return err2
 	})
-	return value
+	if err != nil {
+		return nil, wrapTxErr(err)
+	}
+	return value, nil
 }
@@ -0,0 +1,178 @@
+package circular
+
+import (
+	"bytes"
+
+	bolt "github.com/boltdb/bolt"
+)
+
+// Iterator walks the data keys of a CircularList's bucket with a single
+// bolt.Cursor, without ever touching the meta pointer, so a caller can
+// enumerate, filter, or export the ring without racing Next/Previous.
+type Iterator struct {
+	tx     *bolt.Tx
+	bucket *bolt.Bucket
+	cursor *bolt.Cursor
+	key    []byte
+	value  []byte
+	err    error
+	ownsTx bool
+}
+
+func (it *Iterator) set(k, v []byte) bool {
+	if k == nil || !bytes.HasPrefix(k, dataPrefix) {
+		it.key, it.value = nil, nil
+		return false
+	}
+	it.key, it.value = k, v
+	return true
+}
+
+// First moves to the first element in the ring and reports whether one exists.
+func (it *Iterator) First() bool {
+	return it.set(seekFirstData(it.cursor))
+}
+
+// Last moves to the last element in the ring and reports whether one exists.
+func (it *Iterator) Last() bool {
+	return it.set(seekLastData(it.cursor))
+}
+
+// Next moves to the next element in the ring and reports whether one exists.
+func (it *Iterator) Next() bool {
+	return it.set(nextData(it.cursor))
+}
+
+// Prev moves to the previous element in the ring and reports whether one exists.
+func (it *Iterator) Prev() bool {
+	k, v := it.cursor.Prev()
+	if k == nil || !bytes.HasPrefix(k, dataPrefix) {
+		return it.set(nil, nil)
+	}
+	return it.set(k, v)
+}
+
+// Seek moves to the element at the given 0-based ordinal position,
+// counting from the first element, and reports whether it exists.
+func (it *Iterator) Seek(index int) bool {
+	if index < 0 {
+		it.err = ErrIndexOutOfRange
+		return it.set(nil, nil)
+	}
+	if !it.First() {
+		return false
+	}
+	for i := 0; i < index; i++ {
+		if !it.Next() {
+			return false
+		}
+	}
+	return true
+}
+
+// Key returns the raw storage key of the current element, or nil if the
+// iterator is not positioned on an element.
+func (it *Iterator) Key() []byte {
+	return it.key
+}
+
+// Value returns the value of the current element, or nil if the iterator
+// is not positioned on an element.
+func (it *Iterator) Value() []byte {
+	return it.value
+}
+
+// Err returns the first error encountered while moving the iterator, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close releases resources held by the iterator. It is only necessary for
+// iterators obtained from CircularList.Iterator; iterators handed to a
+// View callback are closed automatically when the callback returns.
+func (it *Iterator) Close() error {
+	if it.ownsTx {
+		return it.tx.Rollback()
+	}
+	return nil
+}
+
+// seekFirstData positions c at the first key with dataPrefix, returning
+// nil, nil if there isn't one.
+func seekFirstData(c *bolt.Cursor) ([]byte, []byte) {
+	k, v := c.Seek(dataPrefix)
+	if k == nil || !bytes.HasPrefix(k, dataPrefix) {
+		return nil, nil
+	}
+	return k, v
+}
+
+// maxDataKey is the highest possible data key: dataPrefix followed by the
+// largest 8-byte big-endian suffix, used to seek to the end of the data
+// keyspace without assuming it sorts last in the bucket (meta keys like
+// "__schema__" can sort above or below it depending on dataPrefix's byte).
+var maxDataKey = append(append([]byte(nil), dataPrefix...), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+
+// seekLastData positions c at the last key with dataPrefix, returning
+// nil, nil if there isn't one.
+func seekLastData(c *bolt.Cursor) ([]byte, []byte) {
+	k, v := c.Seek(maxDataKey)
+	if k != nil && bytes.Equal(k, maxDataKey) {
+		return k, v
+	}
+	k, v = c.Prev()
+	if k == nil || !bytes.HasPrefix(k, dataPrefix) {
+		return nil, nil
+	}
+	return k, v
+}
+
+// nextData advances c and returns nil, nil once it runs past the data keys.
+func nextData(c *bolt.Cursor) ([]byte, []byte) {
+	k, v := c.Next()
+	if k == nil || !bytes.HasPrefix(k, dataPrefix) {
+		return nil, nil
+	}
+	return k, v
+}
+
+// cloneBytes copies b so callers can't mutate bolt's internal buffers.
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	out := make([]byte, len(b))
+	copy(out, b)
+	return out
+}
+
+// Iterator opens a long-lived read-only transaction and returns an Iterator
+// over it. The caller must call Close when done to release the
+// transaction.
+func (cl *CircularList) Iterator() (*Iterator, error) {
+	tx, err := cl.DB.Begin(false)
+	if err != nil {
+		return nil, wrapTxErr(err)
+	}
+	b, err := getBucket(tx, cl.Path)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &Iterator{tx: tx, bucket: b, cursor: b.Cursor(), ownsTx: true}, nil
+}
+
+// View runs fn with an Iterator scoped to a single read transaction, so
+// enumeration, filtering, or export never touches the write pointer and
+// never races Next/Previous/Remove.
+func (cl *CircularList) View(fn func(it *Iterator) error) error {
+	err := cl.DB.View(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		it := &Iterator{tx: tx, bucket: b, cursor: b.Cursor()}
+		return fn(it)
+	})
+	return wrapTxErr(err)
+}
@@ -0,0 +1,177 @@
+package circular
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bolt "github.com/boltdb/bolt"
+)
+
+// currentSchemaVersion is the schema version New and Open stamp fresh
+// buckets with, and the version Open migrates older buckets up to.
+const currentSchemaVersion uint32 = 3
+
+// ErrSchemaTooNew is returned when a bucket's stamped schema version is
+// newer than this package knows how to read.
+var ErrSchemaTooNew = errors.New("circular: bucket schema version is newer than this package supports")
+
+// encodeUint32 converts a uint32 to a 4-byte big-endian slice.
+func encodeUint32(u uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, u)
+	return b
+}
+
+// decodeUint32 converts a 4-byte big-endian slice into a uint32.
+func decodeUint32(b []byte) uint32 {
+	return binary.BigEndian.Uint32(b)
+}
+
+func setSchemaVersion(b *bolt.Bucket, version uint32) error {
+	return b.Put([]byte(metaSchemaKey), encodeUint32(version))
+}
+
+// readSchemaVersion reads the bucket's stamped schema version. A bucket
+// with no __schema__ key predates this feature and is treated as v1.
+func readSchemaVersion(b *bolt.Bucket) uint32 {
+	v := b.Get([]byte(metaSchemaKey))
+	if v == nil {
+		return 1
+	}
+	return decodeUint32(v)
+}
+
+// SchemaVersion returns the on-disk schema version stamped on cl's bucket.
+func (cl *CircularList) SchemaVersion() (uint32, error) {
+	var version uint32
+	err := cl.DB.View(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		version = readSchemaVersion(b)
+		return nil
+	})
+	if err != nil {
+		return 0, wrapTxErr(err)
+	}
+	return version, nil
+}
+
+// migrateBucket runs whatever migrators are needed to bring b from its
+// current schema version up to targetVersion, in a single pass. It
+// refuses to run backwards.
+func migrateBucket(b *bolt.Bucket, targetVersion uint32) error {
+	version := readSchemaVersion(b)
+	if version > targetVersion {
+		return fmt.Errorf("%w: found %d, support up to %d", ErrSchemaTooNew, version, targetVersion)
+	}
+	for version < targetVersion {
+		switch version {
+		case 1:
+			if err := migrateV1ToV2(b); err != nil {
+				return err
+			}
+			version = 2
+		case 2:
+			if err := migrateV2ToV3(b); err != nil {
+				return err
+			}
+			version = 3
+		default:
+			return fmt.Errorf("circular: no migrator from schema version %d to %d", version, targetVersion)
+		}
+	}
+	return nil
+}
+
+// migrateV1ToV2 rewrites every v1 data key (the ascii prefix "i" followed
+// by an 8-byte big-endian index) into the v2 layout (the single reserved
+// type-tag byte in dataPrefix followed by the same 8-byte index), so
+// future record types can share the bucket without ambiguity over the
+// prefix byte. It then stamps the bucket as schema v2.
+func migrateV1ToV2(b *bolt.Bucket) error {
+	oldPrefix := []byte(dataPrefixV1)
+	c := b.Cursor()
+	var oldKeys, newKeys, values [][]byte
+	for k, v := c.Seek(oldPrefix); k != nil && bytes.HasPrefix(k, oldPrefix); k, v = c.Next() {
+		suffix := k[len(oldPrefix):]
+		newKey := append(append([]byte(nil), dataPrefix...), suffix...)
+		oldKeys = append(oldKeys, append([]byte(nil), k...))
+		newKeys = append(newKeys, newKey)
+		values = append(values, append([]byte(nil), v...))
+	}
+	for _, k := range oldKeys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	for i, k := range newKeys {
+		if err := b.Put(k, values[i]); err != nil {
+			return err
+		}
+	}
+	return setSchemaVersion(b, 2)
+}
+
+// migrateV2ToV3 replaces the v2 "count = len(scan-all-keys), current = an
+// index into that slice" representation with a persisted
+// __head__/__tail__/__count__ triple and a current pointer that is itself a
+// raw data key, so navigation becomes a cursor Seek instead of a full scan.
+// It derives head, tail, and count from a single walk of the existing data
+// keys, and re-stamps the old numeric __current__ index as the key found at
+// that ordinal (wrapping to the first key if the index is out of range, the
+// same fallback the v2 navigation code used).
+func migrateV2ToV3(b *bolt.Bucket) error {
+	oldCur := b.Get([]byte(metaCurrentKey))
+	var oldIndex uint64
+	if oldCur != nil {
+		oldIndex = decodeUint64(oldCur)
+	}
+	c := b.Cursor()
+	var head, tail, current []byte
+	var count uint64
+	for k, _ := seekFirstData(c); k != nil; k, _ = nextData(c) {
+		if head == nil {
+			head = append([]byte(nil), k...)
+		}
+		tail = append([]byte(nil), k...)
+		if count == oldIndex {
+			current = append([]byte(nil), k...)
+		}
+		count++
+	}
+	if current == nil {
+		current = head
+	}
+	if err := setMetaHead(b, head); err != nil {
+		return err
+	}
+	if err := setMetaTail(b, tail); err != nil {
+		return err
+	}
+	if err := setMetaCount(b, count); err != nil {
+		return err
+	}
+	if err := setMetaCurrentKey(b, current); err != nil {
+		return err
+	}
+	return setSchemaVersion(b, 3)
+}
+
+// Migrate brings the bucket at path up to targetVersion, running whichever
+// migrators are needed in between. It is equivalent to what Open does
+// automatically, exposed standalone for callers that want to migrate a
+// database offline without otherwise opening the list.
+func Migrate(db *bolt.DB, path []string, targetVersion uint32) error {
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, path)
+		if err != nil {
+			return err
+		}
+		return migrateBucket(b, targetVersion)
+	})
+	return wrapTxErr(err)
+}
@@ -0,0 +1,137 @@
+package circular
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	bolt "github.com/boltdb/bolt"
+)
+
+// List enumerates the names of the buckets nested directly under path,
+// or the top-level buckets in db if path is empty.
+func List(db *bolt.DB, path []string) ([]string, error) {
+	var names []string
+	err := db.View(func(tx *bolt.Tx) error {
+		if len(path) == 0 {
+			return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				names = append(names, string(name))
+				return nil
+			})
+		}
+		b, err := getBucket(tx, path)
+		if err != nil {
+			return err
+		}
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v == nil {
+				names = append(names, string(k))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, wrapTxErr(err)
+	}
+	return names, nil
+}
+
+// Drop deletes the bucket at path, along with everything nested inside it.
+func Drop(db *bolt.DB, path []string) error {
+	if len(path) == 0 {
+		return errors.New("circular: bucket path must not be empty")
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		parent, last := path[:len(path)-1], []byte(path[len(path)-1])
+		if len(parent) == 0 {
+			return tx.DeleteBucket(last)
+		}
+		b, err := getBucket(tx, parent)
+		if err != nil {
+			return err
+		}
+		return b.DeleteBucket(last)
+	})
+	return wrapTxErr(err)
+}
+
+// Registry lazily opens and caches *CircularList handles by bucket path, so
+// many independent rings can share one database file under a common
+// namespace without every caller re-opening the same bucket. Handles are
+// reference-counted: the underlying CircularList is evicted from the cache
+// once its last caller closes it.
+type Registry struct {
+	db      *bolt.DB
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+}
+
+type registryEntry struct {
+	list *CircularList
+	refs int
+}
+
+// NewRegistry creates a Registry backed by db.
+func NewRegistry(db *bolt.DB) *Registry {
+	return &Registry{
+		db:      db,
+		entries: make(map[string]*registryEntry),
+	}
+}
+
+// pathKey renders a bucket path into a cache key.
+func pathKey(path []string) string {
+	return strings.Join(path, "/")
+}
+
+// Open returns the CircularList at path, opening and caching it on first
+// use and incrementing its reference count on every call thereafter.
+// Each successful call must be paired with a Close.
+func (r *Registry) Open(path []string) (*CircularList, error) {
+	key := pathKey(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[key]; ok {
+		e.refs++
+		return e.list, nil
+	}
+	cl, err := Open(r.db, path...)
+	if err != nil {
+		return nil, err
+	}
+	r.entries[key] = &registryEntry{list: cl, refs: 1}
+	return cl, nil
+}
+
+// Close releases one reference to the CircularList at path, evicting it
+// from the cache once its reference count drops to zero. Closing a path
+// that isn't cached is a no-op.
+func (r *Registry) Close(path []string) {
+	key := pathKey(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[key]
+	if !ok {
+		return
+	}
+	e.refs--
+	if e.refs <= 0 {
+		delete(r.entries, key)
+	}
+}
+
+// List enumerates the child ring names nested directly under path.
+func (r *Registry) List(path []string) ([]string, error) {
+	return List(r.db, path)
+}
+
+// Drop removes the ring at path from the registry's cache and deletes its
+// bucket, regardless of outstanding reference count.
+func (r *Registry) Drop(path []string) error {
+	key := pathKey(path)
+	r.mu.Lock()
+	delete(r.entries, key)
+	r.mu.Unlock()
+	return Drop(r.db, path)
+}
@@ -0,0 +1,229 @@
+package circular
+
+import (
+	"bytes"
+	"errors"
+
+	bolt "github.com/boltdb/bolt"
+)
+
+// This file holds the schema-v3 ring primitives: every op works off a
+// persisted __head__/__tail__/__count__ triple plus a current pointer
+// that is itself a raw data key, so Next/Previous/Remove/Add never
+// materialize the full key list the way the v2 representation did.
+
+// getMetaCount reads the persisted element count.
+func getMetaCount(b *bolt.Bucket) (uint64, error) {
+	v := b.Get([]byte(metaCountKey))
+	if v == nil {
+		return 0, errors.New("meta count not found")
+	}
+	return decodeUint64(v), nil
+}
+
+func setMetaCount(b *bolt.Bucket, n uint64) error {
+	return b.Put([]byte(metaCountKey), encodeUint64(n))
+}
+
+func getMetaHead(b *bolt.Bucket) []byte { return b.Get([]byte(metaHeadKey)) }
+func getMetaTail(b *bolt.Bucket) []byte { return b.Get([]byte(metaTailKey)) }
+
+func setMetaHead(b *bolt.Bucket, key []byte) error {
+	if key == nil {
+		return b.Delete([]byte(metaHeadKey))
+	}
+	return b.Put([]byte(metaHeadKey), key)
+}
+
+func setMetaTail(b *bolt.Bucket, key []byte) error {
+	if key == nil {
+		return b.Delete([]byte(metaTailKey))
+	}
+	return b.Put([]byte(metaTailKey), key)
+}
+
+func getMetaCurrentKey(b *bolt.Bucket) []byte { return b.Get([]byte(metaCurrentKey)) }
+
+func setMetaCurrentKey(b *bolt.Bucket, key []byte) error {
+	if key == nil {
+		return b.Delete([]byte(metaCurrentKey))
+	}
+	return b.Put([]byte(metaCurrentKey), key)
+}
+
+// addOne inserts value at the next auto-increment key and extends the
+// ring's tail (and, if the ring was empty, its head and current pointer)
+// in O(1) meta updates, with no scan.
+func addOne(b *bolt.Bucket, value []byte) error {
+	next, err := getMetaNext(b)
+	if err != nil {
+		return err
+	}
+	key := dataKey(int(next))
+	if err := b.Put(key, value); err != nil {
+		return err
+	}
+	if err := setMetaNext(b, next+1); err != nil {
+		return err
+	}
+	count, err := getMetaCount(b)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := setMetaHead(b, key); err != nil {
+			return err
+		}
+		if err := setMetaCurrentKey(b, key); err != nil {
+			return err
+		}
+	}
+	if err := setMetaTail(b, key); err != nil {
+		return err
+	}
+	return setMetaCount(b, count+1)
+}
+
+// valueExists scans the bucket's data keys for one whose value equals v.
+func valueExists(b *bolt.Bucket, v []byte) (bool, error) {
+	c := b.Cursor()
+	for k, val := seekFirstData(c); k != nil; k, val = nextData(c) {
+		if bytes.Equal(val, v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// currentOf returns the current element's value, its 0-based index (found
+// by walking from head), and the total count.
+func currentOf(b *bolt.Bucket) ([]byte, int, int, error) {
+	count, err := getMetaCount(b)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if count == 0 {
+		return nil, 0, 0, ErrEmpty
+	}
+	curKey := getMetaCurrentKey(b)
+	value := cloneBytes(b.Get(curKey))
+	index := 0
+	c := b.Cursor()
+	for k, _ := seekFirstData(c); k != nil && !bytes.Equal(k, curKey); k, _ = nextData(c) {
+		index++
+	}
+	return value, index, int(count), nil
+}
+
+// advance moves the current pointer by one element in the given direction
+// (+1 for Next, -1 for Previous), wrapping via the cached head/tail, and
+// returns the new current element's value.
+func advance(b *bolt.Bucket, direction int) ([]byte, error) {
+	count, err := getMetaCount(b)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrEmpty
+	}
+	curKey := getMetaCurrentKey(b)
+	c := b.Cursor()
+	k, _ := c.Seek(curKey)
+	var nextKey []byte
+	if k != nil && bytes.Equal(k, curKey) {
+		if direction >= 0 {
+			nextKey, _ = nextData(c)
+		} else {
+			nk, _ := c.Prev()
+			if nk != nil && bytes.HasPrefix(nk, dataPrefix) {
+				nextKey = nk
+			}
+		}
+	}
+	if nextKey == nil {
+		if direction >= 0 {
+			nextKey = getMetaHead(b)
+		} else {
+			nextKey = getMetaTail(b)
+		}
+	}
+	if nextKey == nil {
+		return nil, ErrEmpty
+	}
+	if err := setMetaCurrentKey(b, nextKey); err != nil {
+		return nil, err
+	}
+	return cloneBytes(b.Get(nextKey)), nil
+}
+
+// removeCurrent deletes the element the current pointer refers to.
+func removeCurrent(b *bolt.Bucket) error {
+	curKey := getMetaCurrentKey(b)
+	if curKey == nil {
+		return ErrEmpty
+	}
+	return removeKeyBytes(b, curKey)
+}
+
+// removeKeyBytes deletes key from b, fixing up head/tail/count and, if key
+// was the current element, advancing current to the element that follows
+// it (wrapping to head), mirroring the pre-v3 "next element slides into
+// the same slot" behavior.
+func removeKeyBytes(b *bolt.Bucket, key []byte) error {
+	count, err := getMetaCount(b)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrEmpty
+	}
+	c := b.Cursor()
+	k, _ := c.Seek(key)
+	if k == nil || !bytes.Equal(k, key) {
+		return ErrNotFound
+	}
+	nextKey, _ := nextData(c)
+	wasCurrent := bytes.Equal(getMetaCurrentKey(b), key)
+	wasHead := bytes.Equal(getMetaHead(b), key)
+	wasTail := bytes.Equal(getMetaTail(b), key)
+	if err := b.Delete(key); err != nil {
+		return err
+	}
+	newCount := count - 1
+	if newCount == 0 {
+		if err := setMetaHead(b, nil); err != nil {
+			return err
+		}
+		if err := setMetaTail(b, nil); err != nil {
+			return err
+		}
+		if err := setMetaCurrentKey(b, nil); err != nil {
+			return err
+		}
+		return setMetaCount(b, 0)
+	}
+	if err := setMetaCount(b, newCount); err != nil {
+		return err
+	}
+	if wasHead {
+		newHead, _ := seekFirstData(b.Cursor())
+		if err := setMetaHead(b, newHead); err != nil {
+			return err
+		}
+	}
+	if wasTail {
+		newTail, _ := seekLastData(b.Cursor())
+		if err := setMetaTail(b, newTail); err != nil {
+			return err
+		}
+	}
+	if wasCurrent {
+		if nextKey == nil {
+			nextKey = getMetaHead(b)
+		}
+		if err := setMetaCurrentKey(b, nextKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
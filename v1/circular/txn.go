@@ -0,0 +1,69 @@
+package circular
+
+import bolt "github.com/boltdb/bolt"
+
+// ListTxn exposes CircularList's operations against a single bolt
+// transaction, so a caller can batch several otherwise-independent calls
+// (e.g. AddNx then Remove then Next) into one commit instead of one
+// transaction per call.
+type ListTxn struct {
+	bucket *bolt.Bucket
+}
+
+// Update runs fn against a ListTxn scoped to a single read-write
+// transaction on cl's bucket.
+func (cl *CircularList) Update(fn func(txn *ListTxn) error) error {
+	err := cl.DB.Update(func(tx *bolt.Tx) error {
+		b, err := getBucket(tx, cl.Path)
+		if err != nil {
+			return err
+		}
+		return fn(&ListTxn{bucket: b})
+	})
+	return wrapTxErr(err)
+}
+
+// Add inserts a new value into the circular list using the next
+// auto-increment key.
+func (txn *ListTxn) Add(value []byte) error {
+	return addOne(txn.bucket, value)
+}
+
+// AddNx adds a new value only if an identical value is not already present.
+// Returns true if the value was added; otherwise false.
+func (txn *ListTxn) AddNx(value []byte) (bool, error) {
+	found, err := valueExists(txn.bucket, value)
+	if err != nil {
+		return false, err
+	}
+	if found {
+		return false, nil
+	}
+	if err := addOne(txn.bucket, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove deletes the current element from the list and advances the pointer.
+func (txn *ListTxn) Remove() error {
+	return removeCurrent(txn.bucket)
+}
+
+// Next advances the pointer (wrapping around) and returns the next
+// element's value.
+func (txn *ListTxn) Next() ([]byte, error) {
+	return advance(txn.bucket, 1)
+}
+
+// Previous moves the pointer backward (wrapping around) and returns the
+// previous element's value.
+func (txn *ListTxn) Previous() ([]byte, error) {
+	return advance(txn.bucket, -1)
+}
+
+// Current returns the current element's value, its 0-based index, and the
+// total count.
+func (txn *ListTxn) Current() ([]byte, int, int, error) {
+	return currentOf(txn.bucket)
+}
@@ -0,0 +1,189 @@
+package circular
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	bolt "github.com/boltdb/bolt"
+)
+
+// Codec encodes and decodes values of type T to and from the raw bytes
+// stored in a CircularList bucket.
+type Codec[T any] struct {
+	Encode func(T) ([]byte, error)
+	Decode func([]byte) (T, error)
+}
+
+// TypedList wraps a CircularList with a Codec so callers can work with a
+// concrete Go type instead of hand-marshaling []byte themselves.
+type TypedList[T any] struct {
+	*CircularList
+	codec Codec[T]
+}
+
+// NewTyped creates a new typed circular list in the given bucket, deleting
+// any existing bucket with that name.
+func NewTyped[T any](db *bolt.DB, bucketName string, codec Codec[T]) (*TypedList[T], error) {
+	cl, err := New(db, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedList[T]{CircularList: cl, codec: codec}, nil
+}
+
+// OpenTyped opens an existing typed circular list (or creates one if not
+// present) from the given bucket name.
+func OpenTyped[T any](db *bolt.DB, bucketName string, codec Codec[T]) (*TypedList[T], error) {
+	cl, err := Open(db, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedList[T]{CircularList: cl, codec: codec}, nil
+}
+
+// Add encodes value with the list's codec and inserts it into the ring.
+func (tl *TypedList[T]) Add(value T) error {
+	encoded, err := tl.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	return tl.CircularList.Add(encoded)
+}
+
+// AddNx encodes value with the list's codec and adds it only if that exact
+// encoded form is not already present, so equality is judged on the codec's
+// canonical byte form rather than on the caller's T.
+func (tl *TypedList[T]) AddNx(value T) (bool, error) {
+	encoded, err := tl.codec.Encode(value)
+	if err != nil {
+		return false, err
+	}
+	return tl.CircularList.AddNx(encoded)
+}
+
+// Current decodes and returns the current element's value, its 0-based
+// index, and the total count.
+func (tl *TypedList[T]) Current() (T, int, int, error) {
+	raw, index, count, err := tl.CircularList.Current()
+	if err != nil {
+		var zero T
+		return zero, index, count, err
+	}
+	value, err := tl.codec.Decode(raw)
+	return value, index, count, err
+}
+
+// Next advances the pointer (wrapping around) and returns the decoded next element.
+func (tl *TypedList[T]) Next() (T, error) {
+	raw, err := tl.CircularList.Next()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return tl.codec.Decode(raw)
+}
+
+// Previous moves the pointer backward (wrapping around) and returns the
+// decoded previous element.
+func (tl *TypedList[T]) Previous() (T, error) {
+	raw, err := tl.CircularList.Previous()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return tl.codec.Decode(raw)
+}
+
+// StringCodec encodes values as their raw UTF-8 bytes.
+func StringCodec() Codec[string] {
+	return Codec[string]{
+		Encode: func(v string) ([]byte, error) {
+			return []byte(v), nil
+		},
+		Decode: func(b []byte) (string, error) {
+			return string(b), nil
+		},
+	}
+}
+
+// Uint64Codec encodes values as 8-byte big-endian, matching the same
+// encoding CircularList uses internally for its own keys.
+func Uint64Codec() Codec[uint64] {
+	return Codec[uint64]{
+		Encode: func(v uint64) ([]byte, error) {
+			return encodeUint64(v), nil
+		},
+		Decode: func(b []byte) (uint64, error) {
+			if len(b) != 8 {
+				return 0, fmt.Errorf("circular: uint64 codec expects 8 bytes, got %d", len(b))
+			}
+			return decodeUint64(b), nil
+		},
+	}
+}
+
+// JSONCodec encodes values with encoding/json.
+func JSONCodec[T any]() Codec[T] {
+	return Codec[T]{
+		Encode: func(v T) ([]byte, error) {
+			return json.Marshal(v)
+		},
+		Decode: func(b []byte) (T, error) {
+			var v T
+			err := json.Unmarshal(b, &v)
+			return v, err
+		},
+	}
+}
+
+// FieldRecord is a record made up of a fixed-width filterable Header
+// followed by a variable number of payload Fields, so a caller can later
+// add server-side filtering against the header without decoding the
+// payload fields.
+type FieldRecord struct {
+	Header []byte
+	Fields []string
+}
+
+// FieldRecordCodec returns a Codec for FieldRecord that lays each record out
+// as headerWidth bytes of filterable header, followed by each payload field
+// as a 4-byte big-endian length prefix and its raw bytes.
+func FieldRecordCodec(headerWidth int) Codec[FieldRecord] {
+	return Codec[FieldRecord]{
+		Encode: func(r FieldRecord) ([]byte, error) {
+			if len(r.Header) != headerWidth {
+				return nil, fmt.Errorf("circular: field record header must be %d bytes, got %d", headerWidth, len(r.Header))
+			}
+			buf := append([]byte(nil), r.Header...)
+			lenBuf := make([]byte, 4)
+			for _, f := range r.Fields {
+				binary.BigEndian.PutUint32(lenBuf, uint32(len(f)))
+				buf = append(buf, lenBuf...)
+				buf = append(buf, f...)
+			}
+			return buf, nil
+		},
+		Decode: func(b []byte) (FieldRecord, error) {
+			if len(b) < headerWidth {
+				return FieldRecord{}, fmt.Errorf("circular: field record too short for %d-byte header", headerWidth)
+			}
+			header := append([]byte(nil), b[:headerWidth]...)
+			rest := b[headerWidth:]
+			var fields []string
+			for len(rest) > 0 {
+				if len(rest) < 4 {
+					return FieldRecord{}, fmt.Errorf("circular: field record truncated length prefix")
+				}
+				n := binary.BigEndian.Uint32(rest[:4])
+				rest = rest[4:]
+				if uint64(len(rest)) < uint64(n) {
+					return FieldRecord{}, fmt.Errorf("circular: field record truncated field of length %d", n)
+				}
+				fields = append(fields, string(rest[:n]))
+				rest = rest[n:]
+			}
+			return FieldRecord{Header: header, Fields: fields}, nil
+		},
+	}
+}